@@ -1,29 +1,47 @@
 package main
 
 import (
+	"bytes"
+	"container/list"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"html/template"
 	"io"
 	"log"
+	"math/big"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	texttemplate "text/template"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
 type WebhookData struct {
-	ID        string              `json:"id"`
-	Timestamp time.Time           `json:"timestamp"`
-	Headers   map[string][]string `json:"headers"`
-	Body      json.RawMessage     `json:"body"`
-	Method    string              `json:"method"`
-	URL       string              `json:"url"`
+	ID         string              `json:"id"`
+	Timestamp  time.Time           `json:"timestamp"`
+	Headers    map[string][]string `json:"headers"`
+	Body       json.RawMessage     `json:"body"`
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	Verified   bool                `json:"verified"`
+	VerifiedBy string              `json:"verifiedBy,omitempty"`
 }
 
 type WebhookResponse struct {
@@ -33,33 +51,830 @@ type WebhookResponse struct {
 	Delay      int               `json:"delay"` // Delay in milliseconds
 }
 
+// BodyJSONPathMatch asserts that a dot-separated path into the parsed
+// request body JSON equals a given string value.
+type BodyJSONPathMatch struct {
+	Path   string `json:"path"`
+	Equals string `json:"equals"`
+}
+
+// RuleMatch describes the predicates a ResponseRule is evaluated against.
+// All non-empty fields must match for the rule to apply.
+type RuleMatch struct {
+	MethodEquals string              `json:"methodEquals,omitempty"`
+	PathRegex    string              `json:"pathRegex,omitempty"`
+	HeaderEquals map[string]string   `json:"headerEquals,omitempty"`
+	BodyJSONPath []BodyJSONPathMatch `json:"bodyJsonPath,omitempty"`
+}
+
+// ResponseRule is a single named entry in the rule-based response config.
+// Rules are evaluated in priority order (lowest first); the first rule
+// whose Match criteria are satisfied wins.
+type ResponseRule struct {
+	Name       string            `json:"name"`
+	Priority   int               `json:"priority"`
+	Match      RuleMatch         `json:"match"`
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers"`
+	Body       string            `json:"body"`
+	Delay      int               `json:"delay"`
+}
+
+// RetryPolicy controls how a ForwardTarget delivery is retried on failure.
+type RetryPolicy struct {
+	MaxAttempts int     `json:"maxAttempts"`
+	BaseDelayMs int     `json:"baseDelayMs"`
+	Factor      float64 `json:"factor"`
+	JitterMs    int     `json:"jitterMs"`
+}
+
+// ForwardTarget is a single upstream pulung-hooks re-delivers received
+// webhooks to.
+type ForwardTarget struct {
+	URL           string            `json:"url"`
+	Method        string            `json:"method"`
+	Headers       map[string]string `json:"headers"`
+	TimeoutMs     int               `json:"timeoutMs"`
+	Retry         RetryPolicy       `json:"retry"`
+	SigningSecret string            `json:"signingSecret,omitempty"`
+}
+
+// DeadLetterEntry records a forward that exhausted its retry policy, kept
+// around for inspection and manual replay.
+type DeadLetterEntry struct {
+	Webhook    WebhookData `json:"webhook"`
+	TargetURL  string      `json:"targetUrl"`
+	LastError  string      `json:"lastError"`
+	LastStatus int         `json:"lastStatus"`
+	FailedAt   time.Time   `json:"failedAt"`
+}
+
+// VerifyRule describes how to authenticate inbound webhooks from one
+// source: the hex-encoded HMAC over the request body (base64-encoded first
+// when BodyEncoding is base64), computed with Algorithm/Secret, must equal
+// the value of HeaderName (after stripping Prefix, e.g. "sha256=").
+type VerifyRule struct {
+	Name         string `json:"name"`
+	HeaderName   string `json:"headerName"`
+	Algorithm    string `json:"algorithm"` // sha1, sha256, or sha512
+	Secret       string `json:"secret"`
+	Prefix       string `json:"prefix,omitempty"`
+	BodyEncoding string `json:"bodyEncoding,omitempty"` // raw or base64, defaults to raw
+	PathMatch    string `json:"pathMatch,omitempty"`    // regex; empty matches any path
+}
+
+// verifyRuleView is VerifyRule with Secret omitted, for responses. The
+// verify-config GET endpoint has no auth of its own, so echoing back the
+// configured HMAC secrets would let anyone who can read the config forge
+// valid signatures - the one thing signature verification exists to prevent.
+type verifyRuleView struct {
+	Name         string `json:"name"`
+	HeaderName   string `json:"headerName"`
+	Algorithm    string `json:"algorithm"`
+	Prefix       string `json:"prefix,omitempty"`
+	BodyEncoding string `json:"bodyEncoding,omitempty"`
+	PathMatch    string `json:"pathMatch,omitempty"`
+}
+
+func newVerifyRuleView(rule VerifyRule) verifyRuleView {
+	return verifyRuleView{
+		Name:         rule.Name,
+		HeaderName:   rule.HeaderName,
+		Algorithm:    rule.Algorithm,
+		Prefix:       rule.Prefix,
+		BodyEncoding: rule.BodyEncoding,
+		PathMatch:    rule.PathMatch,
+	}
+}
+
+// IncomingRequest captures the parts of an inbound webhook request needed
+// to evaluate RuleMatch predicates against it.
+type IncomingRequest struct {
+	Method  string
+	Path    string
+	Headers map[string][]string
+	Body    []byte
+}
+
+const (
+	forwardWorkerCount      = 5
+	forwardQueueSize        = 1000
+	defaultForwardRetries   = 1
+	defaultForwardBaseDelay = 500 * time.Millisecond
+	defaultForwardFactor    = 2.0
+)
+
+// forwardJob is a unit of work handed to a Forwarder worker. An empty
+// targetURL means "deliver to every configured target" (the normal path out
+// of webhookHandler); a non-empty targetURL restricts delivery to that one
+// target, used by dead-letter replay so retrying one failed delivery doesn't
+// re-send to targets that already succeeded.
+type forwardJob struct {
+	webhook   WebhookData
+	targetURL string
+}
+
+// Forwarder asynchronously re-delivers received webhooks to the configured
+// upstream targets. Deliveries run on a bounded pool of workers reading from
+// an internal channel so webhookHandler's HTTP response never blocks on
+// upstream latency.
+type Forwarder struct {
+	redis      redis.UniversalClient
+	httpClient *http.Client
+	jobs       chan forwardJob
+}
+
+func NewForwarder(rdb redis.UniversalClient) *Forwarder {
+	f := &Forwarder{
+		redis:      rdb,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		jobs:       make(chan forwardJob, forwardQueueSize),
+	}
+	for i := 0; i < forwardWorkerCount; i++ {
+		go f.worker()
+	}
+	return f
+}
+
+// Enqueue schedules a webhook for forwarding to every configured target. It
+// never blocks the caller - if every worker is busy and the queue is full
+// the webhook is dropped and logged rather than backing up the HTTP handler.
+func (f *Forwarder) Enqueue(webhook WebhookData) {
+	f.enqueueJob(forwardJob{webhook: webhook})
+}
+
+// ReplayTarget schedules a single dead-letter entry for redelivery to just
+// the target it originally failed against, not the full configured target
+// list. Same non-blocking drop-on-full-queue behavior as Enqueue.
+func (f *Forwarder) ReplayTarget(webhook WebhookData, targetURL string) {
+	f.enqueueJob(forwardJob{webhook: webhook, targetURL: targetURL})
+}
+
+func (f *Forwarder) enqueueJob(job forwardJob) {
+	select {
+	case f.jobs <- job:
+	default:
+		log.Printf("Forward queue full, dropping webhook %s", job.webhook.ID)
+	}
+}
+
+func (f *Forwarder) worker() {
+	for job := range f.jobs {
+		f.deliver(job)
+	}
+}
+
+func (f *Forwarder) deliver(job forwardJob) {
+	ctx := context.Background()
+
+	targets, err := f.getTargets(ctx)
+	if err != nil {
+		log.Printf("Error loading forward targets: %v", err)
+		return
+	}
+
+	if job.targetURL != "" {
+		for _, target := range targets {
+			if target.URL == job.targetURL {
+				f.deliverToTarget(ctx, job.webhook, target)
+				return
+			}
+		}
+		log.Printf("Replay skipped: no forward target configured for %s anymore", job.targetURL)
+		return
+	}
+
+	for _, target := range targets {
+		f.deliverToTarget(ctx, job.webhook, target)
+	}
+}
+
+func (f *Forwarder) getTargets(ctx context.Context) ([]ForwardTarget, error) {
+	if f.redis == nil {
+		// No Redis connection (STORAGE_BACKEND=memory) - forwarding has
+		// nowhere to read its target config from, so there's nothing to do.
+		return nil, nil
+	}
+
+	data, err := f.redis.Get(ctx, "webhook:forward:config").Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []ForwardTarget
+	if err := json.Unmarshal([]byte(data), &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// deliverToTarget attempts delivery with exponential backoff (plus optional
+// jitter) up to the target's retry policy, dead-lettering the webhook if
+// every attempt fails.
+func (f *Forwarder) deliverToTarget(ctx context.Context, webhook WebhookData, target ForwardTarget) {
+	attempts := target.Retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultForwardRetries
+	}
+	delay := time.Duration(target.Retry.BaseDelayMs) * time.Millisecond
+	if delay <= 0 {
+		delay = defaultForwardBaseDelay
+	}
+	factor := target.Retry.Factor
+	if factor <= 0 {
+		factor = defaultForwardFactor
+	}
+
+	var lastErr error
+	var lastStatus int
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		status, err := f.attemptDelivery(ctx, webhook, target)
+		if err == nil {
+			return
+		}
+		lastErr = err
+		lastStatus = status
+
+		if attempt < attempts {
+			sleep := delay
+			if target.Retry.JitterMs > 0 {
+				jitter, jerr := rand.Int(rand.Reader, big.NewInt(int64(target.Retry.JitterMs)))
+				if jerr == nil {
+					sleep += time.Duration(jitter.Int64()) * time.Millisecond
+				}
+			}
+			time.Sleep(sleep)
+			delay = time.Duration(float64(delay) * factor)
+		}
+	}
+
+	f.deadLetter(ctx, webhook, target, lastErr, lastStatus)
+}
+
+func (f *Forwarder) attemptDelivery(ctx context.Context, webhook WebhookData, target ForwardTarget) (int, error) {
+	method := target.Method
+	if method == "" {
+		method = webhook.Method
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.URL, bytes.NewReader(webhook.Body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for headerKey, headerValue := range target.Headers {
+		req.Header.Set(headerKey, headerValue)
+	}
+	if target.SigningSecret != "" {
+		mac := hmac.New(sha256.New, []byte(target.SigningSecret))
+		mac.Write(webhook.Body)
+		req.Header.Set("X-Pulung-Signature", hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := f.httpClient
+	if target.TimeoutMs > 0 {
+		client = &http.Client{Timeout: time.Duration(target.TimeoutMs) * time.Millisecond}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("upstream returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (f *Forwarder) deadLetter(ctx context.Context, webhook WebhookData, target ForwardTarget, lastErr error, lastStatus int) {
+	entry := DeadLetterEntry{
+		Webhook:    webhook,
+		TargetURL:  target.URL,
+		LastStatus: lastStatus,
+		FailedAt:   time.Now(),
+	}
+	if lastErr != nil {
+		entry.LastError = lastErr.Error()
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Error marshaling dead-letter entry: %v", err)
+		return
+	}
+
+	if f.redis == nil {
+		log.Printf("Forward to %s exhausted retries for webhook %s, dropping (no dead-letter storage without Redis): %v", target.URL, webhook.ID, lastErr)
+		return
+	}
+
+	if err := f.redis.LPush(ctx, "webhooks:deadletter", data).Err(); err != nil {
+		log.Printf("Error pushing dead-letter entry: %v", err)
+	}
+
+	log.Printf("Forward to %s exhausted retries for webhook %s: %v", target.URL, webhook.ID, lastErr)
+}
+
+// ErrNotFound is returned by Store implementations when a webhook or config
+// key has no value, so callers don't need to special-case redis.Nil against
+// non-Redis backends.
+var ErrNotFound = errors.New("store: not found")
+
+// Store abstracts webhook persistence so Server isn't hard-wired to Redis.
+// RedisStore is the original behavior; MemoryStore serves local dev/tests
+// without a Redis dependency; LayeredStore fronts a slower backing store
+// with an in-process LRU for multi-replica deployments.
+type Store interface {
+	Save(ctx context.Context, data WebhookData) error
+	Get(ctx context.Context, id string) (WebhookData, error)
+	List(ctx context.Context, offset, limit int) ([]string, error)
+	Delete(ctx context.Context, ids ...string) error
+	PutConfig(ctx context.Context, key string, value []byte) error
+	GetConfig(ctx context.Context, key string) ([]byte, error)
+}
+
+// newStore selects a Store implementation according to STORAGE_BACKEND
+// (redis|memory|layered, defaulting to redis).
+func newStore(rdb redis.UniversalClient) Store {
+	switch strings.ToLower(os.Getenv("STORAGE_BACKEND")) {
+	case "memory":
+		return NewMemoryStore(1000, 24*time.Hour)
+	case "layered":
+		return NewLayeredStore(NewRedisStore(rdb), rdb, 1000, 24*time.Hour)
+	default:
+		return NewRedisStore(rdb)
+	}
+}
+
+// RedisStore is the original persistence behavior: webhooks are stored as
+// individual keys with a 24h TTL, with their ids tracked in webhooks:list
+// (most recent first, capped at 1000).
+type RedisStore struct {
+	redis redis.UniversalClient
+}
+
+func NewRedisStore(rdb redis.UniversalClient) *RedisStore {
+	return &RedisStore{redis: rdb}
+}
+
+func (rs *RedisStore) Save(ctx context.Context, data WebhookData) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if err := rs.redis.Set(ctx, data.ID, jsonData, 24*time.Hour).Err(); err != nil {
+		return err
+	}
+	if err := rs.redis.LPush(ctx, "webhooks:list", data.ID).Err(); err != nil {
+		return err
+	}
+	return rs.redis.LTrim(ctx, "webhooks:list", 0, 999).Err()
+}
+
+func (rs *RedisStore) Get(ctx context.Context, id string) (WebhookData, error) {
+	raw, err := rs.redis.Get(ctx, id).Result()
+	if err == redis.Nil {
+		return WebhookData{}, ErrNotFound
+	}
+	if err != nil {
+		return WebhookData{}, err
+	}
+
+	var webhook WebhookData
+	if err := json.Unmarshal([]byte(raw), &webhook); err != nil {
+		return WebhookData{}, err
+	}
+	return webhook, nil
+}
+
+// List returns up to limit ids from webhooks:list starting at offset, in
+// most-recent-first order. A negative limit returns everything from offset
+// onward.
+func (rs *RedisStore) List(ctx context.Context, offset, limit int) ([]string, error) {
+	if limit == 0 {
+		return nil, nil
+	}
+	stop := int64(-1)
+	if limit >= 0 {
+		stop = int64(offset + limit - 1)
+	}
+	return rs.redis.LRange(ctx, "webhooks:list", int64(offset), stop).Result()
+}
+
+// Delete removes each id with its own Del call rather than one multi-key
+// Del(ids...). In Cluster mode keys commonly land in different hash slots,
+// and a multi-key command spanning slots fails with CROSSSLOT - issuing one
+// command per key keeps this working across standalone, Sentinel, and
+// Cluster alike.
+func (rs *RedisStore) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if err := rs.redis.Del(ctx, id).Err(); err != nil {
+			return err
+		}
+		if err := rs.redis.LRem(ctx, "webhooks:list", 0, id).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs *RedisStore) PutConfig(ctx context.Context, key string, value []byte) error {
+	return rs.redis.Set(ctx, key, value, 0).Err()
+}
+
+func (rs *RedisStore) GetConfig(ctx context.Context, key string) ([]byte, error) {
+	data, err := rs.redis.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// lruEntry is a single cached value in an lruCache. seq is the write-order
+// sequence number assigned when the entry was last set, independent of the
+// recency list - see lruCache.keys.
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+	seq       int64
+}
+
+// lruCache is a bounded, optionally TTL'd cache built on the standard
+// doubly-linked-list + map combination, recency-ordered front-to-back. The
+// recency list drives eviction only; keys() reports write order separately
+// via each entry's seq so reads don't reshuffle List ordering.
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	ll         *list.List
+	items      map[string]*list.Element
+	nextSeq    int64
+}
+
+func newLRUCache(maxEntries int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*lruEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *lruCache) set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	c.nextSeq++
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		entry.seq = c.nextSeq
+		return
+	}
+
+	el := c.ll.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt, seq: c.nextSeq})
+	c.items[key] = el
+
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruEntry).key)
+		}
+	}
+}
+
+func (c *lruCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// keys returns cached keys in write order, most recently set first. This is
+// deliberately independent of the recency list used for LRU eviction -
+// get() moves entries to the front of that list on every read, and List
+// ordering must not change just because something happened to be read.
+func (c *lruCache) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]*lruEntry, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		entries = append(entries, el.Value.(*lruEntry))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq > entries[j].seq
+	})
+
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.key
+	}
+	return keys
+}
+
+// MemoryStore is an in-process Store with no external dependency, useful
+// for local development and tests that don't want to stand up Redis.
+// Webhook data is LRU-capped; config entries are few and kept unbounded.
+type MemoryStore struct {
+	data   *lruCache
+	config *lruCache
+}
+
+func NewMemoryStore(maxEntries int, ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		data:   newLRUCache(maxEntries, ttl),
+		config: newLRUCache(0, 0),
+	}
+}
+
+func (m *MemoryStore) Save(ctx context.Context, data WebhookData) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	m.data.set(data.ID, jsonData)
+	return nil
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (WebhookData, error) {
+	raw, ok := m.data.get(id)
+	if !ok {
+		return WebhookData{}, ErrNotFound
+	}
+
+	var webhook WebhookData
+	if err := json.Unmarshal(raw, &webhook); err != nil {
+		return WebhookData{}, err
+	}
+	return webhook, nil
+}
+
+func (m *MemoryStore) List(ctx context.Context, offset, limit int) ([]string, error) {
+	keys := m.data.keys()
+	if offset >= len(keys) {
+		return nil, nil
+	}
+
+	end := len(keys)
+	if limit >= 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return keys[offset:end], nil
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		m.data.delete(id)
+	}
+	return nil
+}
+
+func (m *MemoryStore) PutConfig(ctx context.Context, key string, value []byte) error {
+	m.config.set(key, value)
+	return nil
+}
+
+func (m *MemoryStore) GetConfig(ctx context.Context, key string) ([]byte, error) {
+	raw, ok := m.config.get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return raw, nil
+}
+
+// LayeredStore fronts a slower backing Store with an in-process LRU, so
+// reads that hit the cache avoid a round trip to the backing store. Writes
+// and deletes publish an invalidate:<key> message over Redis pub/sub so
+// other replicas evict their own cached copy instead of serving stale data.
+type LayeredStore struct {
+	instanceID string
+	backing    Store
+	cache      *MemoryStore
+	redis      redis.UniversalClient
+}
+
+func NewLayeredStore(backing Store, rdb redis.UniversalClient, maxEntries int, ttl time.Duration) *LayeredStore {
+	ls := &LayeredStore{
+		instanceID: newUUID(),
+		backing:    backing,
+		cache:      NewMemoryStore(maxEntries, ttl),
+		redis:      rdb,
+	}
+	go ls.listenInvalidations()
+	return ls
+}
+
+func (ls *LayeredStore) listenInvalidations() {
+	ctx := context.Background()
+	sub := ls.redis.Subscribe(ctx, "webhooks:invalidate")
+	defer sub.Close()
+
+	for msg := range sub.Channel() {
+		origin, rest, ok := strings.Cut(msg.Payload, ":")
+		if !ok || origin == ls.instanceID {
+			continue // ignore our own writes - we've already cached the fresh value
+		}
+		key := strings.TrimPrefix(rest, "invalidate:")
+		ls.cache.data.delete(key)
+		ls.cache.config.delete(key)
+	}
+}
+
+func (ls *LayeredStore) publishInvalidate(ctx context.Context, key string) {
+	payload := ls.instanceID + ":invalidate:" + key
+	if err := ls.redis.Publish(ctx, "webhooks:invalidate", payload).Err(); err != nil {
+		log.Printf("Error publishing cache invalidation for %s: %v", key, err)
+	}
+}
+
+func (ls *LayeredStore) Save(ctx context.Context, data WebhookData) error {
+	if err := ls.backing.Save(ctx, data); err != nil {
+		return err
+	}
+	jsonData, _ := json.Marshal(data)
+	ls.cache.data.set(data.ID, jsonData)
+	ls.publishInvalidate(ctx, data.ID)
+	return nil
+}
+
+func (ls *LayeredStore) Get(ctx context.Context, id string) (WebhookData, error) {
+	if webhook, err := ls.cache.Get(ctx, id); err == nil {
+		return webhook, nil
+	}
+
+	webhook, err := ls.backing.Get(ctx, id)
+	if err != nil {
+		return WebhookData{}, err
+	}
+	if jsonData, err := json.Marshal(webhook); err == nil {
+		ls.cache.data.set(id, jsonData)
+	}
+	return webhook, nil
+}
+
+func (ls *LayeredStore) List(ctx context.Context, offset, limit int) ([]string, error) {
+	// Ordering must reflect the global view across replicas, so List always
+	// goes to the backing store rather than the local cache.
+	return ls.backing.List(ctx, offset, limit)
+}
+
+func (ls *LayeredStore) Delete(ctx context.Context, ids ...string) error {
+	if err := ls.backing.Delete(ctx, ids...); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		ls.cache.data.delete(id)
+		ls.publishInvalidate(ctx, id)
+	}
+	return nil
+}
+
+func (ls *LayeredStore) PutConfig(ctx context.Context, key string, value []byte) error {
+	if err := ls.backing.PutConfig(ctx, key, value); err != nil {
+		return err
+	}
+	ls.cache.config.set(key, value)
+	ls.publishInvalidate(ctx, key)
+	return nil
+}
+
+func (ls *LayeredStore) GetConfig(ctx context.Context, key string) ([]byte, error) {
+	if value, err := ls.cache.GetConfig(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, err := ls.backing.GetConfig(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	ls.cache.config.set(key, value)
+	return value, nil
+}
+
 type Server struct {
-	redis *redis.Client
+	redis     redis.UniversalClient // nil when STORAGE_BACKEND=memory; guard before use
+	store     Store
+	forwarder *Forwarder
 }
 
+// NewServer wires up the Store selected by STORAGE_BACKEND. Only the "memory"
+// backend runs without a Redis connection - "redis" and "layered" both need
+// one, the latter for its cross-replica invalidation pub/sub. Everything
+// that optionally uses s.redis (SSE publish/subscribe, the dead-letter list,
+// the forwarder's target config) must nil-check it rather than assume it's
+// set, so memory mode actually runs standalone.
 func NewServer() *Server {
-	// Get Redis connection details from environment or use defaults
-	redistHost := os.Getenv("REDIS_HOST")
-	if redistHost == "" {
-		redistHost = "localhost"
+	var rdb redis.UniversalClient
+	if strings.ToLower(os.Getenv("STORAGE_BACKEND")) != "memory" {
+		rdb = newRedisClient()
 	}
-	redistPort := os.Getenv("REDIS_PORT")
-	if redistPort == "" {
-		redistPort = "6379"
+	return &Server{
+		redis:     rdb,
+		store:     newStore(rdb),
+		forwarder: NewForwarder(rdb),
 	}
-	redisAddr := fmt.Sprintf("%s:%s", redistHost, redistPort)
+}
+
+// newRedisClient builds a Redis client according to REDIS_MODE
+// (standalone|sentinel|cluster, defaulting to standalone) so pulung-hooks
+// can be pointed at a single node, a Sentinel-managed deployment, or a
+// Cluster without code changes. redis.UniversalClient lets the rest of the
+// server (Set, LPush, LTrim, LRange, Del, Get, Ping, Subscribe, ...) use the
+// same call surface regardless of which mode is active.
+func newRedisClient() redis.UniversalClient {
 	redisPassword := os.Getenv("REDIS_AUTH")
-	redisDB := 0 // Default Redis DB
 
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: redisPassword,
-		DB:       redisDB,
-	})
+	switch strings.ToLower(os.Getenv("REDIS_MODE")) {
+	case "sentinel":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       os.Getenv("REDIS_SENTINEL_MASTER"),
+			SentinelAddrs:    splitAddrs(os.Getenv("REDIS_SENTINEL_ADDRS")),
+			SentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+			Password:         redisPassword,
+		})
 
-	return &Server{
-		redis: rdb,
+	case "cluster":
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    splitAddrs(os.Getenv("REDIS_CLUSTER_ADDRS")),
+			Password: redisPassword,
+		})
+
+	default:
+		redisHost := os.Getenv("REDIS_HOST")
+		if redisHost == "" {
+			redisHost = "localhost"
+		}
+		redisPort := os.Getenv("REDIS_PORT")
+		if redisPort == "" {
+			redisPort = "6379"
+		}
+
+		return redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%s", redisHost, redisPort),
+			Password: redisPassword,
+			DB:       0,
+		})
+	}
+}
+
+// splitAddrs parses a comma-separated list of host:port addresses, as used
+// by REDIS_SENTINEL_ADDRS and REDIS_CLUSTER_ADDRS.
+func splitAddrs(addrs string) []string {
+	if addrs == "" {
+		return nil
 	}
+
+	var result []string
+	for _, addr := range strings.Split(addrs, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			result = append(result, addr)
+		}
+	}
+	return result
 }
 
 func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
@@ -78,116 +893,476 @@ func (s *Server) webhookHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	// Generate a unique key for Redis
-	key := fmt.Sprintf("webhook:%d", time.Now().UnixNano())
+	// Verify the inbound signature, if a rule is configured for this path.
+	// A configured rule that fails to verify must reject the request before
+	// anything is persisted.
+	ctx := context.Background()
+	verifyRules, err := s.getVerifyRules(ctx)
+	if err != nil {
+		log.Printf("Error loading verify rules: %v", err)
+	}
+
+	matched, verifyOK, ruleName, err := verifyIncomingRequest(verifyRules, r.URL.Path, r.Header, body)
+	if err != nil {
+		log.Printf("Error verifying webhook signature: %v", err)
+	}
+	if matched && !verifyOK {
+		log.Printf("Rejected webhook: signature verification failed for rule %q", ruleName)
+		http.Error(w, "Signature verification failed", http.StatusUnauthorized)
+		return
+	}
+
+	// Generate a unique key for Redis
+	key := fmt.Sprintf("webhook:%d", time.Now().UnixNano())
+
+	// Create webhook data structure
+	webhookData := WebhookData{
+		ID:         key,
+		Timestamp:  time.Now(),
+		Headers:    r.Header,
+		Body:       json.RawMessage(body),
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		Verified:   matched && verifyOK,
+		VerifiedBy: ruleName,
+	}
+
+	// Convert to JSON
+	jsonData, err := json.Marshal(webhookData)
+	if err != nil {
+		log.Printf("Error marshaling webhook data: %v", err)
+		http.Error(w, "Error processing webhook data", http.StatusInternalServerError)
+		return
+	}
+
+	// Save via the configured storage backend
+	if err := s.store.Save(ctx, webhookData); err != nil {
+		log.Printf("Error saving webhook data: %v", err)
+		http.Error(w, "Error saving webhook data", http.StatusInternalServerError)
+		return
+	}
+
+	// Publish to subscribers of the live tail stream; best-effort, a publish
+	// failure must not affect the caller's response. No-op without Redis
+	// (STORAGE_BACKEND=memory) - there's nothing for webhooksStreamHandler
+	// to subscribe to in that mode.
+	if s.redis != nil {
+		if err := s.redis.Publish(ctx, "webhooks:events", jsonData).Err(); err != nil {
+			log.Printf("Error publishing webhook event: %v", err)
+		}
+	}
+
+	// Hand off to the forwarding subsystem; delivery happens on a worker
+	// goroutine so upstream latency never delays this response
+	s.forwarder.Enqueue(webhookData)
+
+	log.Printf("Webhook received and saved with key: %s", key)
+
+	// Get configured response: first rule to match the incoming request
+	// wins, falling back to the unconditional default response config
+	incomingReq := IncomingRequest{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Headers: r.Header,
+		Body:    body,
+	}
+	responseConfig := s.resolveWebhookResponse(ctx, incomingReq)
+	responseConfig = renderWebhookResponse(responseConfig, incomingReq, r.URL.String())
+
+	// Apply delay if configured
+	if responseConfig.Delay > 0 {
+		time.Sleep(time.Duration(responseConfig.Delay) * time.Millisecond)
+	}
+
+	// Set custom headers
+	for headerKey, headerValue := range responseConfig.Headers {
+		w.Header().Set(headerKey, headerValue)
+	}
+
+	// Set content type if not already set
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	// Set status code
+	w.WriteHeader(responseConfig.StatusCode)
+
+	// Write response body
+	if responseConfig.Body != "" {
+		w.Write([]byte(responseConfig.Body))
+	} else {
+		// Default response
+		fmt.Fprintf(w, `{"status":"success","message":"Webhook received and saved","key":"%s"}`, key)
+	}
+}
+
+func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
+	// With STORAGE_BACKEND=memory there's no Redis connection to check -
+	// the store itself is in-process, so it's healthy by construction.
+	if s.redis == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"status":"healthy","redis":"disabled"}`)
+		return
+	}
+
+	ctx := context.Background()
+	_, err := s.redis.Ping(ctx).Result()
+	if err != nil {
+		http.Error(w, "Redis connection failed", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, `{"status":"healthy","redis":"connected"}`)
+}
+
+func defaultWebhookResponse() WebhookResponse {
+	return WebhookResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       "",
+		Delay:      0,
+	}
+}
+
+// getResponseRules loads the sorted rule list from the store. Rules are stored
+// as a single JSON array under webhook:response:rules, sorted by Priority
+// ascending so the first match in the slice is the first match by priority.
+func (s *Server) getResponseRules(ctx context.Context) ([]ResponseRule, error) {
+	data, err := s.store.GetConfig(ctx, "webhook:response:rules")
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []ResponseRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *Server) saveResponseRules(ctx context.Context, rules []ResponseRule) error {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority < rules[j].Priority
+	})
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	return s.store.PutConfig(ctx, "webhook:response:rules", data)
+}
+
+// matchesRule reports whether an incoming request satisfies every predicate
+// set on the rule. A zero-value Match (no predicates set) never matches -
+// use the unconditional default response config for a catch-all instead.
+func matchesRule(rule ResponseRule, req IncomingRequest) bool {
+	m := rule.Match
+	matched := false
+
+	if m.MethodEquals != "" {
+		if !strings.EqualFold(m.MethodEquals, req.Method) {
+			return false
+		}
+		matched = true
+	}
+
+	if m.PathRegex != "" {
+		re, err := regexp.Compile(m.PathRegex)
+		if err != nil || !re.MatchString(req.Path) {
+			return false
+		}
+		matched = true
+	}
+
+	for headerName, wantValue := range m.HeaderEquals {
+		values := req.Headers[http.CanonicalHeaderKey(headerName)]
+		found := false
+		for _, v := range values {
+			if v == wantValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+		matched = true
+	}
+
+	if len(m.BodyJSONPath) > 0 {
+		var parsed interface{}
+		if err := json.Unmarshal(req.Body, &parsed); err != nil {
+			return false
+		}
+		for _, jp := range m.BodyJSONPath {
+			value, ok := lookupJSONPath(parsed, jp.Path)
+			if !ok || fmt.Sprintf("%v", value) != jp.Equals {
+				return false
+			}
+		}
+		matched = true
+	}
+
+	return matched
+}
+
+// lookupJSONPath walks a dot-separated path (e.g. "order.customer.id")
+// through a decoded JSON value and returns the value found at that path.
+func lookupJSONPath(value interface{}, path string) (interface{}, bool) {
+	current := value
+	for _, segment := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func (s *Server) getVerifyRules(ctx context.Context) ([]VerifyRule, error) {
+	data, err := s.store.GetConfig(ctx, "webhook:verify:config")
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []VerifyRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *Server) saveVerifyRules(ctx context.Context, rules []VerifyRule) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	return s.store.PutConfig(ctx, "webhook:verify:config", data)
+}
+
+// computeHMAC returns the hex-encoded HMAC of body under the given
+// algorithm and secret. An unrecognized algorithm is reported via error
+// rather than silently falling back, since a typo there must not cause
+// verification to pass unintentionally.
+func computeHMAC(algorithm, secret string, body []byte) (string, error) {
+	var h func() hash.Hash
+	switch strings.ToLower(algorithm) {
+	case "sha1":
+		h = sha1.New
+	case "sha256":
+		h = sha256.New
+	case "sha512":
+		h = sha512.New
+	default:
+		return "", fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verifyIncomingRequest finds the first VerifyRule whose PathMatch applies
+// to the request path and checks the request's HMAC against it. matched is
+// false when no rule applies to this path, in which case the request is
+// passed through unverified. When matched is true, ok reports whether the
+// signature was valid.
+func verifyIncomingRequest(rules []VerifyRule, path string, headers map[string][]string, body []byte) (matched bool, ok bool, ruleName string, err error) {
+	for _, rule := range rules {
+		if rule.PathMatch != "" {
+			re, reErr := regexp.Compile(rule.PathMatch)
+			if reErr != nil || !re.MatchString(path) {
+				continue
+			}
+		}
+
+		signedBody := body
+		if strings.EqualFold(rule.BodyEncoding, "base64") {
+			signedBody = []byte(base64.StdEncoding.EncodeToString(body))
+		}
+
+		expected, hmacErr := computeHMAC(rule.Algorithm, rule.Secret, signedBody)
+		if hmacErr != nil {
+			return true, false, rule.Name, hmacErr
+		}
+
+		values := headers[http.CanonicalHeaderKey(rule.HeaderName)]
+		if len(values) == 0 {
+			return true, false, rule.Name, nil
+		}
+		actual := strings.TrimPrefix(values[0], rule.Prefix)
+
+		if hmac.Equal([]byte(expected), []byte(actual)) {
+			return true, true, rule.Name, nil
+		}
+		return true, false, rule.Name, nil
+	}
+
+	return false, false, "", nil
+}
+
+// TemplateContext is the data made available to response body/header
+// templates so they can echo fields from the incoming request back to the
+// caller - the common need in integration tests that expect correlated IDs.
+type TemplateContext struct {
+	Body    interface{}
+	Headers map[string][]string
+	Method  string
+	URL     string
+	Query   url.Values
+	Now     time.Time
+	UUID    string
+}
 
-	// Create webhook data structure
-	webhookData := WebhookData{
-		ID:        key,
-		Timestamp: time.Now(),
-		Headers:   r.Header,
-		Body:      json.RawMessage(body),
-		Method:    r.Method,
-		URL:       r.URL.String(),
-	}
+func newTemplateContext(req IncomingRequest, rawURL string) TemplateContext {
+	var parsedBody interface{}
+	_ = json.Unmarshal(req.Body, &parsedBody) // templates see nil Body on non-JSON payloads
 
-	// Convert to JSON
-	jsonData, err := json.Marshal(webhookData)
-	if err != nil {
-		log.Printf("Error marshaling webhook data: %v", err)
-		http.Error(w, "Error processing webhook data", http.StatusInternalServerError)
-		return
+	query := url.Values{}
+	if parsed, err := url.Parse(rawURL); err == nil {
+		query = parsed.Query()
 	}
 
-	// Save to Redis
-	ctx := context.Background()
-	err = s.redis.Set(ctx, key, jsonData, 24*time.Hour).Err() // TTL of 24 hours
-	if err != nil {
-		log.Printf("Error saving to Redis: %v", err)
-		http.Error(w, "Error saving webhook data", http.StatusInternalServerError)
-		return
+	return TemplateContext{
+		Body:    parsedBody,
+		Headers: req.Headers,
+		Method:  req.Method,
+		URL:     rawURL,
+		Query:   query,
+		Now:     time.Now(),
+		UUID:    newUUID(),
 	}
+}
 
-	// Also add to a list for easy retrieval
-	err = s.redis.LPush(ctx, "webhooks:list", key).Err()
-	if err != nil {
-		log.Printf("Error adding to webhooks list: %v", err)
-	}
+// templateFuncs are the helpers exposed to response templates.
+var templateFuncs = texttemplate.FuncMap{
+	"jsonPath": func(value interface{}, path string) interface{} {
+		result, _ := lookupJSONPath(value, path)
+		return result
+	},
+	"b64enc": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"hmacSha256": func(secret, message string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(message))
+		return hex.EncodeToString(mac.Sum(nil))
+	},
+	"randInt": func(min, max int) int {
+		if max <= min {
+			return min
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min)))
+		if err != nil {
+			return min
+		}
+		return min + int(n.Int64())
+	},
+}
 
-	// Trim the list to keep only the last 1000 webhooks
-	err = s.redis.LTrim(ctx, "webhooks:list", 0, 999).Err()
-	if err != nil {
-		log.Printf("Error trimming webhooks list: %v", err)
+// newUUID generates a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
 	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
 
-	log.Printf("Webhook received and saved with key: %s", key)
-
-	// Get configured response or use default
-	responseConfig := s.getWebhookResponse(ctx)
+// parseResponseTemplate validates that a response body or header value is a
+// well-formed text/template, without rendering it. Used at config-write
+// time so operators get feedback immediately instead of a broken response
+// at request time.
+func parseResponseTemplate(text string) error {
+	_, err := texttemplate.New("response").Funcs(templateFuncs).Parse(text)
+	return err
+}
 
-	// Apply delay if configured
-	if responseConfig.Delay > 0 {
-		time.Sleep(time.Duration(responseConfig.Delay) * time.Millisecond)
+// renderResponseTemplate executes a response body or header value as a
+// text/template against the incoming request's TemplateContext. Templates
+// that fail to parse or execute are returned verbatim so a bad template
+// degrades to a static string rather than breaking delivery.
+func renderResponseTemplate(text string, ctx TemplateContext) string {
+	tmpl, err := texttemplate.New("response").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return text
 	}
 
-	// Set custom headers
-	for headerKey, headerValue := range responseConfig.Headers {
-		w.Header().Set(headerKey, headerValue)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return text
 	}
+	return buf.String()
+}
 
-	// Set content type if not already set
-	if w.Header().Get("Content-Type") == "" {
-		w.Header().Set("Content-Type", "application/json")
-	}
+// renderWebhookResponse renders the Body and Headers of a WebhookResponse
+// as templates against the incoming request before it is written out.
+func renderWebhookResponse(resp WebhookResponse, req IncomingRequest, rawURL string) WebhookResponse {
+	ctx := newTemplateContext(req, rawURL)
 
-	// Set status code
-	w.WriteHeader(responseConfig.StatusCode)
+	resp.Body = renderResponseTemplate(resp.Body, ctx)
 
-	// Write response body
-	if responseConfig.Body != "" {
-		w.Write([]byte(responseConfig.Body))
-	} else {
-		// Default response
-		fmt.Fprintf(w, `{"status":"success","message":"Webhook received and saved","key":"%s"}`, key)
+	if len(resp.Headers) > 0 {
+		rendered := make(map[string]string, len(resp.Headers))
+		for k, v := range resp.Headers {
+			rendered[k] = renderResponseTemplate(v, ctx)
+		}
+		resp.Headers = rendered
 	}
+
+	return resp
 }
 
-func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
-	// Check Redis connection
-	ctx := context.Background()
-	_, err := s.redis.Ping(ctx).Result()
+// resolveWebhookResponse evaluates the configured rules against an incoming
+// request and returns the first match's response, falling back to the
+// unconditional default response config when no rule matches.
+func (s *Server) resolveWebhookResponse(ctx context.Context, req IncomingRequest) WebhookResponse {
+	rules, err := s.getResponseRules(ctx)
 	if err != nil {
-		http.Error(w, "Redis connection failed", http.StatusServiceUnavailable)
-		return
+		log.Printf("Error loading response rules: %v", err)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprint(w, `{"status":"healthy","redis":"connected"}`)
+	for _, rule := range rules {
+		if matchesRule(rule, req) {
+			return WebhookResponse{
+				StatusCode: rule.StatusCode,
+				Headers:    rule.Headers,
+				Body:       rule.Body,
+				Delay:      rule.Delay,
+			}
+		}
+	}
+
+	return s.getWebhookResponse(ctx)
 }
 
 func (s *Server) getWebhookResponse(ctx context.Context) WebhookResponse {
-	// Get response config from Redis or return default
-	data, err := s.redis.Get(ctx, "webhook:response:config").Result()
+	// Get response config from the store or return default
+	data, err := s.store.GetConfig(ctx, "webhook:response:config")
 	if err != nil {
-		// Return default response
-		return WebhookResponse{
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-			Body:       "",
-			Delay:      0,
-		}
+		return defaultWebhookResponse()
 	}
 
 	var response WebhookResponse
-	if err := json.Unmarshal([]byte(data), &response); err != nil {
+	if err := json.Unmarshal(data, &response); err != nil {
 		// Return default on parse error
-		return WebhookResponse{
-			StatusCode: 200,
-			Headers:    map[string]string{"Content-Type": "application/json"},
-			Body:       "",
-			Delay:      0,
-		}
+		return defaultWebhookResponse()
 	}
 
 	return response
@@ -215,7 +1390,7 @@ func (s *Server) apiWebhooksHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the list of webhook keys
-	keys, err := s.redis.LRange(ctx, "webhooks:list", 0, int64(limit*2)).Result() // Get more to allow for filtering
+	keys, err := s.store.List(ctx, 0, limit*2) // Get more to allow for filtering
 	if err != nil {
 		log.Printf("Error getting webhooks list: %v", err)
 		http.Error(w, "Error retrieving webhooks", http.StatusInternalServerError)
@@ -224,18 +1399,12 @@ func (s *Server) apiWebhooksHandler(w http.ResponseWriter, r *http.Request) {
 
 	var webhooks []WebhookData
 	for _, key := range keys {
-		data, err := s.redis.Get(ctx, key).Result()
+		webhook, err := s.store.Get(ctx, key)
 		if err != nil {
 			log.Printf("Error getting webhook data for key %s: %v", key, err)
 			continue
 		}
 
-		var webhook WebhookData
-		if err := json.Unmarshal([]byte(data), &webhook); err != nil {
-			log.Printf("Error unmarshaling webhook data for key %s: %v", key, err)
-			continue
-		}
-
 		// Apply search filter
 		if search != "" {
 			bodyStr := strings.ToLower(string(webhook.Body))
@@ -291,6 +1460,96 @@ func (s *Server) listWebhooksHandler(w http.ResponseWriter, r *http.Request) {
 	s.apiWebhooksHandler(w, r)
 }
 
+// streamFilter holds the per-client query params used to narrow the live
+// tail stream to the webhooks a client actually cares about.
+type streamFilter struct {
+	method string
+	prefix string
+	search string
+}
+
+func (f streamFilter) matches(webhook WebhookData) bool {
+	if f.method != "" && !strings.EqualFold(f.method, webhook.Method) {
+		return false
+	}
+	if f.prefix != "" && !strings.HasPrefix(webhook.URL, f.prefix) {
+		return false
+	}
+	if f.search != "" {
+		haystack := strings.ToLower(webhook.Method + " " + webhook.URL + " " + string(webhook.Body))
+		if !strings.Contains(haystack, strings.ToLower(f.search)) {
+			return false
+		}
+	}
+	return true
+}
+
+// webhooksStreamHandler tails newly received webhooks over Server-Sent
+// Events so dashboard clients don't have to poll /api/webhooks. Each event
+// published to webhooks:events by webhookHandler is forwarded to connected
+// clients that match the request's filter params, with a heartbeat comment
+// every 15s to keep intermediate proxies from closing the connection.
+func (s *Server) webhooksStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	if s.redis == nil {
+		http.Error(w, "Live tail requires a Redis-backed storage backend", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter := streamFilter{
+		method: r.URL.Query().Get("method"),
+		prefix: r.URL.Query().Get("prefix"),
+		search: r.URL.Query().Get("search"),
+	}
+
+	ctx := r.Context()
+	sub := s.redis.Subscribe(ctx, "webhooks:events")
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var webhook WebhookData
+			if err := json.Unmarshal([]byte(msg.Payload), &webhook); err != nil {
+				log.Printf("Error unmarshaling streamed webhook: %v", err)
+				continue
+			}
+			if !filter.matches(webhook) {
+				continue
+			}
+
+			fmt.Fprintf(w, "data: %s\n\n", msg.Payload)
+			flusher.Flush()
+
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
 func (s *Server) dashboardHandler(w http.ResponseWriter, r *http.Request) {
 	tmpl, err := template.ParseFiles("templates/webhooks.html")
 	if err != nil {
@@ -306,6 +1565,85 @@ func (s *Server) dashboardHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// verifyConfigHandler manages the list of VerifyRule entries used to
+// authenticate inbound webhooks (GET returns the current list, POST
+// replaces it wholesale).
+func (s *Server) verifyConfigHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := s.getVerifyRules(ctx)
+		if err != nil {
+			log.Printf("Error loading verify rules: %v", err)
+			http.Error(w, "Error retrieving verify config", http.StatusInternalServerError)
+			return
+		}
+
+		views := make([]verifyRuleView, len(rules))
+		for i, rule := range rules {
+			views[i] = newVerifyRuleView(rule)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rules": views,
+			"count": len(views),
+		})
+
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Printf("Error reading request body: %v", err)
+			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		var rules []VerifyRule
+		if err := json.Unmarshal(body, &rules); err != nil {
+			log.Printf("Error parsing verify config: %v", err)
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+
+		for _, rule := range rules {
+			if rule.Name == "" || rule.HeaderName == "" || rule.Secret == "" {
+				http.Error(w, "Each rule requires name, headerName, and secret", http.StatusBadRequest)
+				return
+			}
+			switch strings.ToLower(rule.Algorithm) {
+			case "sha1", "sha256", "sha512":
+			default:
+				http.Error(w, fmt.Sprintf("Unsupported algorithm %q for rule %q", rule.Algorithm, rule.Name), http.StatusBadRequest)
+				return
+			}
+			if rule.PathMatch != "" {
+				if _, err := regexp.Compile(rule.PathMatch); err != nil {
+					http.Error(w, fmt.Sprintf("Invalid pathMatch for rule %q: %v", rule.Name, err), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+
+		if err := s.saveVerifyRules(ctx, rules); err != nil {
+			log.Printf("Error saving verify config: %v", err)
+			http.Error(w, "Error saving verify config", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Verify configuration updated: %d rule(s)", len(rules))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"status":"success","message":"Verify configuration updated"}`)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 func (s *Server) getResponseConfigHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -356,7 +1694,20 @@ func (s *Server) setResponseConfigHandler(w http.ResponseWriter, r *http.Request
 		responseConfig.Headers = make(map[string]string)
 	}
 
-	// Save to Redis
+	// Validate Body/Headers as text/template source now, so a typo surfaces
+	// here instead of breaking every webhook delivery at request time
+	if err := parseResponseTemplate(responseConfig.Body); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid body template: %v", err), http.StatusBadRequest)
+		return
+	}
+	for headerKey, headerValue := range responseConfig.Headers {
+		if err := parseResponseTemplate(headerValue); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid template for header %q: %v", headerKey, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Save via the configured storage backend
 	ctx := context.Background()
 	configData, err := json.Marshal(responseConfig)
 	if err != nil {
@@ -365,9 +1716,8 @@ func (s *Server) setResponseConfigHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = s.redis.Set(ctx, "webhook:response:config", configData, 0).Err() // No TTL
-	if err != nil {
-		log.Printf("Error saving response config to Redis: %v", err)
+	if err := s.store.PutConfig(ctx, "webhook:response:config", configData); err != nil {
+		log.Printf("Error saving response config: %v", err)
 		http.Error(w, "Error saving configuration", http.StatusInternalServerError)
 		return
 	}
@@ -379,6 +1729,229 @@ func (s *Server) setResponseConfigHandler(w http.ResponseWriter, r *http.Request
 	fmt.Fprint(w, `{"status":"success","message":"Response configuration updated"}`)
 }
 
+func (s *Server) responseRulesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	switch r.Method {
+	case http.MethodGet:
+		rules, err := s.getResponseRules(ctx)
+		if err != nil {
+			log.Printf("Error loading response rules: %v", err)
+			http.Error(w, "Error retrieving rules", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rules": rules,
+			"count": len(rules),
+		})
+
+	case http.MethodPost:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Printf("Error reading request body: %v", err)
+			http.Error(w, "Error reading request body", http.StatusBadRequest)
+			return
+		}
+		defer r.Body.Close()
+
+		var rule ResponseRule
+		if err := json.Unmarshal(body, &rule); err != nil {
+			log.Printf("Error parsing rule: %v", err)
+			http.Error(w, "Invalid JSON format", http.StatusBadRequest)
+			return
+		}
+
+		if rule.Name == "" {
+			http.Error(w, "Rule name is required", http.StatusBadRequest)
+			return
+		}
+		if rule.StatusCode < 100 || rule.StatusCode > 599 {
+			rule.StatusCode = 200
+		}
+		if rule.Delay < 0 || rule.Delay > 30000 {
+			rule.Delay = 0
+		}
+		if rule.Headers == nil {
+			rule.Headers = make(map[string]string)
+		}
+		if rule.Match.PathRegex != "" {
+			if _, err := regexp.Compile(rule.Match.PathRegex); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid pathRegex: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if err := parseResponseTemplate(rule.Body); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid body template: %v", err), http.StatusBadRequest)
+			return
+		}
+		for headerKey, headerValue := range rule.Headers {
+			if err := parseResponseTemplate(headerValue); err != nil {
+				http.Error(w, fmt.Sprintf("Invalid template for header %q: %v", headerKey, err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		rules, err := s.getResponseRules(ctx)
+		if err != nil {
+			log.Printf("Error loading response rules: %v", err)
+			http.Error(w, "Error retrieving rules", http.StatusInternalServerError)
+			return
+		}
+
+		replaced := false
+		for i, existing := range rules {
+			if existing.Name == rule.Name {
+				rules[i] = rule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rules = append(rules, rule)
+		}
+
+		if err := s.saveResponseRules(ctx, rules); err != nil {
+			log.Printf("Error saving response rules: %v", err)
+			http.Error(w, "Error saving rule", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Response rule upserted: name=%s priority=%d", rule.Name, rule.Priority)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"status":"success","message":"Rule saved"}`)
+
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			http.Error(w, "name query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		rules, err := s.getResponseRules(ctx)
+		if err != nil {
+			log.Printf("Error loading response rules: %v", err)
+			http.Error(w, "Error retrieving rules", http.StatusInternalServerError)
+			return
+		}
+
+		remaining := rules[:0]
+		for _, existing := range rules {
+			if existing.Name != name {
+				remaining = append(remaining, existing)
+			}
+		}
+
+		if err := s.saveResponseRules(ctx, remaining); err != nil {
+			log.Printf("Error saving response rules: %v", err)
+			http.Error(w, "Error deleting rule", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("Response rule deleted: name=%s", name)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"status":"success","message":"Rule deleted"}`)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// deadLetterHandler lists forwarding failures that exhausted their retry
+// policy (GET) and allows replaying one back through the forwarder (POST).
+func (s *Server) deadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := context.Background()
+
+	if s.redis == nil {
+		http.Error(w, "Dead-letter queue requires a Redis-backed storage backend", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		limit := int64(100)
+		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+			if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+				limit = int64(parsed)
+				if limit > 500 {
+					limit = 500
+				}
+			}
+		}
+
+		raw, err := s.redis.LRange(ctx, "webhooks:deadletter", 0, limit-1).Result()
+		if err != nil {
+			log.Printf("Error getting dead-letter list: %v", err)
+			http.Error(w, "Error retrieving dead-letter entries", http.StatusInternalServerError)
+			return
+		}
+
+		entries := make([]DeadLetterEntry, 0, len(raw))
+		for _, item := range raw {
+			var entry DeadLetterEntry
+			if err := json.Unmarshal([]byte(item), &entry); err != nil {
+				log.Printf("Error unmarshaling dead-letter entry: %v", err)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"entries": entries,
+			"count":   len(entries),
+		})
+
+	case http.MethodPost:
+		index, err := strconv.Atoi(r.URL.Query().Get("index"))
+		if err != nil || index < 0 {
+			http.Error(w, "index query parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		raw, err := s.redis.LIndex(ctx, "webhooks:deadletter", int64(index)).Result()
+		if err == redis.Nil {
+			http.Error(w, "No dead-letter entry at that index", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Error reading dead-letter entry: %v", err)
+			http.Error(w, "Error retrieving dead-letter entry", http.StatusInternalServerError)
+			return
+		}
+
+		var entry DeadLetterEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			log.Printf("Error unmarshaling dead-letter entry: %v", err)
+			http.Error(w, "Corrupt dead-letter entry", http.StatusInternalServerError)
+			return
+		}
+
+		if err := s.redis.LRem(ctx, "webhooks:deadletter", 1, raw).Err(); err != nil {
+			log.Printf("Error removing dead-letter entry: %v", err)
+		}
+
+		s.forwarder.ReplayTarget(entry.Webhook, entry.TargetURL)
+
+		log.Printf("Replaying dead-letter entry for webhook %s to %s", entry.Webhook.ID, entry.TargetURL)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"status":"success","message":"Entry re-enqueued"}`)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 func (s *Server) clearAllWebhooksHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -388,7 +1961,7 @@ func (s *Server) clearAllWebhooksHandler(w http.ResponseWriter, r *http.Request)
 	ctx := context.Background()
 
 	// Get all webhook keys from the list
-	keys, err := s.redis.LRange(ctx, "webhooks:list", 0, -1).Result()
+	keys, err := s.store.List(ctx, 0, -1)
 	if err != nil {
 		log.Printf("Error getting webhooks list: %v", err)
 		http.Error(w, "Error retrieving webhooks", http.StatusInternalServerError)
@@ -397,22 +1970,13 @@ func (s *Server) clearAllWebhooksHandler(w http.ResponseWriter, r *http.Request)
 
 	// Delete all individual webhook data
 	if len(keys) > 0 {
-		err = s.redis.Del(ctx, keys...).Err()
-		if err != nil {
+		if err := s.store.Delete(ctx, keys...); err != nil {
 			log.Printf("Error deleting webhook data: %v", err)
 			http.Error(w, "Error clearing webhooks", http.StatusInternalServerError)
 			return
 		}
 	}
 
-	// Clear the webhooks list
-	err = s.redis.Del(ctx, "webhooks:list").Err()
-	if err != nil {
-		log.Printf("Error clearing webhooks list: %v", err)
-		http.Error(w, "Error clearing webhooks list", http.StatusInternalServerError)
-		return
-	}
-
 	log.Printf("Cleared %d webhooks", len(keys))
 
 	w.Header().Set("Content-Type", "application/json")
@@ -423,22 +1987,31 @@ func (s *Server) clearAllWebhooksHandler(w http.ResponseWriter, r *http.Request)
 func main() {
 	server := NewServer()
 
-	// Test Redis connection
-	ctx := context.Background()
-	_, err := server.redis.Ping(ctx).Result()
-	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+	// Test Redis connection, unless STORAGE_BACKEND=memory opted out of Redis
+	// entirely - that mode is meant to run standalone for local dev/tests.
+	if server.redis != nil {
+		ctx := context.Background()
+		_, err := server.redis.Ping(ctx).Result()
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		log.Println("Successfully connected to Redis")
+	} else {
+		log.Println("STORAGE_BACKEND=memory: running without a Redis connection")
 	}
-	log.Println("Successfully connected to Redis")
 
 	// Setup HTTP routes
 	http.HandleFunc("/webhook", server.webhookHandler)
 	http.HandleFunc("/health", server.healthHandler)
 	http.HandleFunc("/webhooks", server.listWebhooksHandler)
 	http.HandleFunc("/api/webhooks", server.apiWebhooksHandler)
+	http.HandleFunc("/api/webhooks/stream", server.webhooksStreamHandler)
+	http.HandleFunc("/api/webhooks/deadletter", server.deadLetterHandler)
 	http.HandleFunc("/api/webhooks/clear", server.clearAllWebhooksHandler)
 	http.HandleFunc("/api/response-config", server.getResponseConfigHandler)
 	http.HandleFunc("/api/response-config/set", server.setResponseConfigHandler)
+	http.HandleFunc("/api/response-config/rules", server.responseRulesHandler)
+	http.HandleFunc("/api/verify-config", server.verifyConfigHandler)
 	http.HandleFunc("/", server.dashboardHandler)
 	http.HandleFunc("/dashboard", server.dashboardHandler)
 